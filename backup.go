@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// createBackup mirror-clones the repo into backupDir before history is
+// rewritten, so there is a restore point if something goes wrong. It
+// returns the path to the created clone.
+func createBackup(backupDir string) string {
+	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+		log.Fatalf("Could not create backup dir %s: %v", backupDir, err)
+	}
+
+	repoName := filepath.Base(strings.TrimRight(*repoAbsolutePath, string(filepath.Separator)))
+	dest := filepath.Join(backupDir, fmt.Sprintf("%s-%s.git", repoName, time.Now().Format("20060102-150405")))
+
+	cmd := exec.Command("git", "clone", "--mirror", *repoAbsolutePath, dest)
+	if err := cmd.Run(); err != nil {
+		log.Fatalf("Could not create backup mirror clone at %s: %v", dest, err)
+	}
+
+	return dest
+}
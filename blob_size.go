@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// blobSizeInfo aggregates, for a single path, the total size of every
+// blob git has ever stored for it across all of history, along with how
+// many commits touched it.
+type blobSizeInfo struct {
+	Path             string
+	UncompressedSize int64
+	DiskSize         int64
+	CommitCount      int
+}
+
+// objectSize is the size of a single git object, as reported by
+// https://git-scm.com/docs/git-cat-file#Documentation/git-cat-file.txt---batch-checkltformatgt
+type objectSize struct {
+	Uncompressed int64
+	Disk         int64
+}
+
+// pathToObjects maps every path ever saved in git to the list of blob
+// object ids it has pointed to over the repository's history, using
+// https://git-scm.com/docs/git-rev-list.
+func pathToObjects() map[string][]string {
+	cmd := exec.Command("git", "rev-list", "--objects", "--all")
+	lines, err := runCmdAndGetOutputLines(cmd)
+	if err != nil {
+		log.Fatalf("Could not find all objects: %v", err)
+	}
+
+	objects := make(map[string][]string)
+	for _, line := range lines {
+		splitted := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		// There are some objects that don't correspond to file names.
+		if len(splitted) == 2 {
+			objects[splitted[1]] = append(objects[splitted[1]], splitted[0])
+		}
+	}
+	return objects
+}
+
+// commitCountsByPath returns, for every path ever saved in git, the
+// number of commits across all branches that touched it.
+func commitCountsByPath() map[string]int {
+	cmd := exec.Command("git", "log", "--all", "--name-only", "--format=")
+	lines, err := runCmdAndGetOutputLines(cmd)
+	if err != nil {
+		log.Fatalf("Could not walk commit history: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, line := range lines {
+		if path := strings.TrimSpace(line); path != "" {
+			counts[path]++
+		}
+	}
+	return counts
+}
+
+// batchObjectSizes looks up the uncompressed and on-disk size of every
+// id in objectIDs with a single https://git-scm.com/docs/git-cat-file
+// --batch-check invocation, rather than one process per object.
+func batchObjectSizes(objectIDs []string) map[string]objectSize {
+	cmd := exec.Command("git", "cat-file", "--batch-check=%(objectname) %(objecttype) %(objectsize) %(objectsize:disk)")
+	cmd.Dir = *repoAbsolutePath
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		log.Fatalf("Could not open stdin to git cat-file: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		log.Fatalf("Could not open stdout from git cat-file: %v", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		log.Fatalf("Could not start git cat-file: %v", err)
+	}
+
+	go func() {
+		writer := bufio.NewWriter(stdin)
+		for _, id := range objectIDs {
+			fmt.Fprintln(writer, id)
+		}
+		writer.Flush()
+		stdin.Close()
+	}()
+
+	sizes := make(map[string]objectSize, len(objectIDs))
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		uncompressed, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		disk, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[fields[0]] = objectSize{Uncompressed: uncompressed, Disk: disk}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("Could not read git cat-file output: %v", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		log.Fatalf("git cat-file failed: %v", err)
+	}
+
+	return sizes
+}
+
+// computeBlobSizeInfo ranks every path git has ever stored an object
+// for by the total size of all of its historical versions, largest
+// first.
+func computeBlobSizeInfo() []blobSizeInfo {
+	pathObjects := pathToObjects()
+	commitCounts := commitCountsByPath()
+
+	objectIDs := make([]string, 0, len(pathObjects))
+	for _, ids := range pathObjects {
+		objectIDs = append(objectIDs, ids...)
+	}
+	sizes := batchObjectSizes(objectIDs)
+
+	// git rev-list --objects --all also returns tree (directory) object
+	// ids alongside blobs; batchObjectSizes only records an entry for
+	// ids it confirms are blobs, so a path with no entry in sizes is a
+	// directory and must be skipped, or it would show up as a bogus 0 B
+	// row.
+	infos := make([]blobSizeInfo, 0, len(pathObjects))
+	for path, ids := range pathObjects {
+		info := blobSizeInfo{Path: path, CommitCount: commitCounts[path]}
+		isBlob := false
+		for _, id := range ids {
+			size, ok := sizes[id]
+			if !ok {
+				continue
+			}
+			isBlob = true
+			info.UncompressedSize += size.Uncompressed
+			info.DiskSize += size.Disk
+		}
+		if !isBlob {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].UncompressedSize > infos[j].UncompressedSize
+	})
+
+	return infos
+}
+
+// selectBySize narrows blobSizeInfo down to what a user asked to see
+// via --top-n and/or --min-size. A topN of 0 means no limit on count,
+// and a minSize of 0 means no limit on size.
+func selectBySize(infos []blobSizeInfo, topN int, minSize int64) []blobSizeInfo {
+	selected := make([]blobSizeInfo, 0)
+	for _, info := range infos {
+		if minSize > 0 && info.UncompressedSize < minSize {
+			continue
+		}
+		selected = append(selected, info)
+		if topN > 0 && len(selected) >= topN {
+			break
+		}
+	}
+	return selected
+}
+
+// printBlobSizeTable prints a table of the largest blobs found in the
+// repository's history, ordered from largest to smallest.
+func printBlobSizeTable(infos []blobSizeInfo) {
+	fmt.Printf("%-60s %12s %12s %10s\n", "PATH", "SIZE", "ON DISK", "COMMITS")
+	for _, info := range infos {
+		fmt.Printf("%-60s %12s %12s %10d\n", info.Path, humanizeBytes(info.UncompressedSize), humanizeBytes(info.DiskSize), info.CommitCount)
+	}
+}
+
+// humanizeBytes renders n as a human-readable size, e.g. "12.3 MiB".
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// parseSize parses a human-provided size such as "10MB" or "512KB" into
+// a byte count. A bare number is interpreted as bytes. An empty string
+// parses to 0, meaning "no limit".
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"GB", 1024 * 1024 * 1024},
+		{"MB", 1024 * 1024},
+		{"KB", 1024},
+		{"B", 1},
+	}
+
+	upper := strings.ToUpper(s)
+	for _, u := range units {
+		if strings.HasSuffix(upper, u.suffix) {
+			numberPart := strings.TrimSpace(s[:len(s)-len(u.suffix)])
+			value, err := strconv.ParseFloat(numberPart, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size %q: %w", s, err)
+			}
+			return int64(value * float64(u.factor)), nil
+		}
+	}
+
+	value, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{name: "empty means no limit", in: "", want: 0},
+		{name: "bare number", in: "512", want: 512},
+		{name: "bytes suffix", in: "10B", want: 10},
+		{name: "kilobytes", in: "10KB", want: 10 * 1024},
+		{name: "megabytes", in: "10MB", want: 10 * 1024 * 1024},
+		{name: "gigabytes", in: "1GB", want: 1024 * 1024 * 1024},
+		{name: "fractional megabytes", in: "1.5MB", want: int64(1.5 * 1024 * 1024)},
+		{name: "lowercase suffix", in: "10mb", want: 10 * 1024 * 1024},
+		{name: "whitespace", in: "  10MB  ", want: 10 * 1024 * 1024},
+		{name: "invalid", in: "not-a-size", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectBySize(t *testing.T) {
+	infos := []blobSizeInfo{
+		{Path: "a", UncompressedSize: 100},
+		{Path: "b", UncompressedSize: 50},
+		{Path: "c", UncompressedSize: 10},
+	}
+
+	tests := []struct {
+		name    string
+		topN    int
+		minSize int64
+		want    []string
+	}{
+		{name: "no limit", topN: 0, minSize: 0, want: []string{"a", "b", "c"}},
+		{name: "topN limits count", topN: 2, minSize: 0, want: []string{"a", "b"}},
+		{name: "minSize filters small entries", topN: 0, minSize: 51, want: []string{"a"}},
+		{name: "topN and minSize combined", topN: 1, minSize: 11, want: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := selectBySize(infos, tt.topN, tt.minSize)
+			if len(selected) != len(tt.want) {
+				t.Fatalf("selectBySize() = %v, want paths %v", selected, tt.want)
+			}
+			for i, info := range selected {
+				if info.Path != tt.want[i] {
+					t.Errorf("selectBySize()[%d].Path = %q, want %q", i, info.Path, tt.want[i])
+				}
+			}
+		})
+	}
+}
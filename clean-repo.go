@@ -28,6 +28,48 @@ var repoAbsolutePath = flag.String("repo-absolute-path", "", "The absolute path
 // the repoAbsolutePath/ will be considered as garbage.
 var mainBranchName = flag.String("main-branch-name", "", "The name of the main branch (e.g. master)")
 
+// mode picks the heuristic used to find files to remove from history.
+// "stale" is the original heuristic (files missing on the main branch or
+// gitignored); "size" instead ranks every path by how much history size
+// it is responsible for, which is the more common reason people reach
+// for history rewriting in the first place.
+var mode = flag.String("mode", "stale", "Which heuristic to use to find files: \"stale\" (missing on main branch or gitignored) or \"size\" (largest blobs in history, see --top-n/--min-size)")
+
+// topN and minSizeFlag only apply to --mode=size.
+var topN = flag.Int("top-n", 0, "In --mode=size, only show the N largest paths (0 means no limit)")
+var minSizeFlag = flag.String("min-size", "", "In --mode=size, only show paths whose total historical size is at least this, e.g. 10MB")
+
+// batchSize controls how many paths are passed to a single git
+// filter-repo invocation. Rewriting history is expensive, so batching
+// paths together instead of invoking filter-repo once per path gives an
+// order-of-magnitude speedup on repos with many paths to prune.
+var batchSize = flag.Int("batch-size", 0, "Maximum number of paths passed to a single git filter-repo invocation (0 means no limit, i.e. all paths in one invocation)")
+
+// dryRun, when set, prints everything that would be removed and how
+// much space it would reclaim, but never invokes git filter-repo.
+var dryRun = flag.Bool("dry-run", false, "Print what would be removed and how much space would be reclaimed, without rewriting any history")
+
+// backupDirFlag, when set, makes the tool mirror-clone the repo into it
+// before rewriting history, so there is a restore point if something
+// goes wrong.
+var backupDirFlag = flag.String("backup-dir", "", "Directory to mirror-clone the repo into before rewriting history, as a restore point")
+
+// interactive picks whether the candidate paths are reviewed one by one
+// in a terminal picker instead of a single Yes/No prompt. It is ignored
+// (and the plain prompt is used instead) when stdin/stdout aren't a
+// TTY, e.g. when running in CI.
+var interactive = flag.Bool("interactive", true, "Review candidate paths one by one in a terminal picker instead of a single Yes/No prompt (ignored when not running in a TTY)")
+
+// candidate is a path found by one of the selection heuristics, along
+// with why it was picked. Detail carries extra, heuristic-specific
+// context (e.g. the redacted line a secret scan matched on) and is
+// empty for heuristics that don't have any to show.
+type candidate struct {
+	Path   string
+	Reason string
+	Detail string
+}
+
 // checkoutToMainBranch uses: https://git-scm.com/docs/git-checkout
 func checkoutToMainBranch() {
 	cmd := exec.Command("git", "checkout", *mainBranchName)
@@ -99,36 +141,56 @@ func isFileGitIgnored(file string) bool {
 }
 
 // filterFilesToBeRemoved returns the files that are either:
-// - not found in the directory of the repo after checking out to the
-//   main branch or
-// - ignored by git.
-func filterFilesToBeRemoved(files []string) []string {
-	filesToBeRemoved := make([]string, 0)
+//   - not found in the directory of the repo after checking out to the
+//     main branch or
+//   - ignored by git.
+func filterFilesToBeRemoved(files []string) []candidate {
+	candidates := make([]candidate, 0)
 	for _, file := range files {
-		if !isFileInRepoDir(file) || isFileGitIgnored(file) {
-			filesToBeRemoved = append(filesToBeRemoved, file)
+		if !isFileInRepoDir(file) {
+			candidates = append(candidates, candidate{Path: file, Reason: "missing on main branch"})
+		} else if isFileGitIgnored(file) {
+			candidates = append(candidates, candidate{Path: file, Reason: "gitignored"})
 		}
 	}
-	return filesToBeRemoved
+	return candidates
+}
+
+// candidatePaths extracts the Path of every candidate, in order.
+func candidatePaths(candidates []candidate) []string {
+	paths := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		paths = append(paths, c.Path)
+	}
+	return paths
 }
 
-func printFilesToBeRemoved(files []string) {
+func printFilesToBeRemoved(candidates []candidate) {
 	fmt.Print(ConsoleRedColorCode)
 	fmt.Printf("All of the following files will be removed either because they are ignored by git or because they are not present in the repo directory on branch %s\n", *mainBranchName)
 	fmt.Print(ConsoleResetColorCode)
 
-	for _, file := range files {
-		fmt.Println(file)
+	for _, c := range candidates {
+		fmt.Printf("%s (%s)\n", c.Path, c.Reason)
+		if c.Detail != "" {
+			fmt.Printf("  %s\n", c.Detail)
+		}
 	}
 }
 
 // takeUserConsent exits the process if the user doesn't agree to
-// remove the filtered files.
-func takeUserConsent() {
+// remove the filtered files. If backupPath is non-empty, it is
+// mentioned in the prompt as the restore point created before
+// rewriting.
+func takeUserConsent(backupPath string) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print(ConsoleRedColorCode)
-	fmt.Println("Start cleaning up the git objects mentioned above? (Yes/No) [Default: No]")
+	if backupPath != "" {
+		fmt.Printf("Start cleaning up the git objects mentioned above? A backup mirror clone was created at %s. (Yes/No) [Default: No]\n", backupPath)
+	} else {
+		fmt.Println("Start cleaning up the git objects mentioned above? (Yes/No) [Default: No]")
+	}
 	fmt.Print(ConsoleResetColorCode)
 
 	text, _ := reader.ReadString('\n')
@@ -137,27 +199,6 @@ func takeUserConsent() {
 	}
 }
 
-// removeFilesFromHistory applies
-// https://github.com/newren/git-filter-repo/blob/main/COPYING
-// on all of the files to be removed.
-func removeFilesFromHistory(files []string) {
-	for _, file := range files {
-
-		fmt.Println()
-		fmt.Print(ConsoleGreenColorCode)
-		fmt.Printf("Will starting removing file: %s\n", file)
-		fmt.Println(ConsoleResetColorCode)
-
-		cmd := exec.Command("git", "filter-repo", "--force", "--invert-paths", "--path", file)
-		cmd.Dir = *repoAbsolutePath
-		cmd.Stdout = os.Stdout
-		cmd.Stdin = os.Stdin
-		if err := cmd.Run(); err != nil {
-			log.Fatalf("Failed to rewrite history to remove file: %s: %v", file, err)
-		}
-	}
-}
-
 // printFiltering keeps informing the user through stdout that it is
 // filtering until it receives a signal on the stop channel.
 func printFiltering(stop <-chan struct{}) {
@@ -192,6 +233,13 @@ func main() {
 
 	checkoutToMainBranch()
 
+	if *mode == "size" {
+		runSizeMode()
+		return
+	} else if *mode != "stale" {
+		log.Fatalf("Unknown --mode %q, must be \"stale\" or \"size\"", *mode)
+	}
+
 	files := getAllFilesSavedInGit()
 	initFilesLen := len(files)
 	fmt.Printf("Git is currently saving objects for %d files.\n", initFilesLen)
@@ -200,12 +248,64 @@ func main() {
 	go func() {
 		printFiltering(filteringDone)
 	}()
-	filesToBeRemoved := filterFilesToBeRemoved(files)
+	candidates := filterFilesToBeRemoved(files)
 	close(filteringDone)
 
-	printFilesToBeRemoved(filesToBeRemoved)
-	takeUserConsent()
-	removeFilesFromHistory(filesToBeRemoved)
+	cfg, err := resolveConfig()
+	if err != nil {
+		log.Fatalf("Could not load --config: %v", err)
+	}
+	candidates = applyConfig(cfg, candidates, files)
+
+	if *scanSecrets {
+		detectors, err := compileDetectors(cfg)
+		if err != nil {
+			log.Fatalf("Could not set up secret detectors: %v", err)
+		}
+		candidates = mergeCandidates(candidates, scanBlobsForSecrets(detectors))
+		// A protected path must never appear in the removal list, even
+		// when the secret scan (rather than the earlier heuristics) is
+		// what flagged it.
+		candidates = filterProtected(cfg, candidates)
+	}
+
+	if *dryRun {
+		printFilesToBeRemoved(candidates)
+		printDryRunReport(candidates)
+		return
+	}
+
+	var backupPath string
+	if *backupDirFlag != "" {
+		backupPath = createBackup(*backupDirFlag)
+	}
+
+	if shouldUseInteractivePicker() {
+		selected, ok := runInteractivePicker(candidates)
+		if !ok {
+			log.Fatal("User cancelled the picker")
+		}
+		candidates = selected
+	} else {
+		printFilesToBeRemoved(candidates)
+		takeUserConsent(backupPath)
+	}
+
+	removeFilesFromHistory(candidatePaths(candidates))
 
 	fmt.Printf("Git was saving %d objects and now is saving %d objects.\n", initFilesLen, len(getAllFilesSavedInGit()))
 }
+
+// runSizeMode prints a ranked table of the largest blobs in the
+// repository's history, without removing anything. It is meant to help
+// users decide what to prune with --mode=stale.
+func runSizeMode() {
+	minSize, err := parseSize(*minSizeFlag)
+	if err != nil {
+		log.Fatalf("Invalid --min-size: %v", err)
+	}
+
+	infos := computeBlobSizeInfo()
+	infos = selectBySize(infos, *topN, minSize)
+	printBlobSizeTable(infos)
+}
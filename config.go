@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// Config declares prune policy on top of the built-in heuristics:
+// IncludeGlobs force matching paths into the removal candidate set even
+// if no heuristic flagged them, while ExcludeGlobs and ProtectPaths keep
+// matching paths out of it even if a heuristic or an include glob did.
+// Globs support ** to match across directory separators.
+// SecretDetectors lets a config file extend the built-in secret-scan
+// detectors used by --scan-secrets (see secrets.go).
+type Config struct {
+	IncludeGlobs    []string         `yaml:"include_globs"`
+	ExcludeGlobs    []string         `yaml:"exclude_globs"`
+	ProtectPaths    []string         `yaml:"protect_paths"`
+	SecretDetectors []SecretDetector `yaml:"secret_detectors"`
+}
+
+// configPath points at an optional YAML file holding a Config.
+var configPath = flag.String("config", "", "Path to a YAML config file declaring include_globs/exclude_globs/protect_paths rules")
+
+// includeGlobFlags, excludeGlobFlags and protectPathFlags let the same
+// rules be passed on the command line instead of (or in addition to) a
+// config file.
+var includeGlobFlags stringSliceFlag
+var excludeGlobFlags stringSliceFlag
+var protectPathFlags stringSliceFlag
+
+func init() {
+	flag.Var(&includeGlobFlags, "include-glob", "Glob (supports **) of paths to always treat as removal candidates; repeatable")
+	flag.Var(&excludeGlobFlags, "exclude-glob", "Glob (supports **) of paths to never treat as removal candidates; repeatable")
+	flag.Var(&protectPathFlags, "protect-path", "Glob (supports **) of paths to always protect, even if gitignored or included by another rule; repeatable")
+}
+
+// stringSliceFlag implements flag.Value to accumulate repeated flag
+// occurrences into a slice, e.g. --protect-path a --protect-path b.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// loadConfig reads and parses a YAML config file at path.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// resolveConfig merges the optional --config file with the repeatable
+// --include-glob/--exclude-glob/--protect-path flags into one Config.
+func resolveConfig() (*Config, error) {
+	cfg := &Config{}
+	if *configPath != "" {
+		fileCfg, err := loadConfig(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg = fileCfg
+	}
+
+	cfg.IncludeGlobs = append(cfg.IncludeGlobs, includeGlobFlags...)
+	cfg.ExcludeGlobs = append(cfg.ExcludeGlobs, excludeGlobFlags...)
+	cfg.ProtectPaths = append(cfg.ProtectPaths, protectPathFlags...)
+
+	return cfg, nil
+}
+
+// matchesAny reports whether path matches any of the given glob
+// patterns.
+func matchesAny(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterProtected drops any candidate matching cfg's ProtectPaths or
+// ExcludeGlobs. It is also meant to be re-applied after merging in
+// candidates from another heuristic (e.g. --scan-secrets), since a
+// protected path must never appear in the removal list regardless of
+// which heuristic flagged it.
+func filterProtected(cfg *Config, candidates []candidate) []candidate {
+	result := make([]candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if matchesAny(c.Path, cfg.ProtectPaths) || matchesAny(c.Path, cfg.ExcludeGlobs) {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// applyConfig folds cfg's include/exclude/protect rules into
+// candidates, given every path git has ever saved (allFiles), so an
+// include glob can surface a path no heuristic flagged and a protect
+// path never appears in the result.
+func applyConfig(cfg *Config, candidates []candidate, allFiles []string) []candidate {
+	result := filterProtected(cfg, candidates)
+	seen := make(map[string]bool, len(result))
+	for _, c := range result {
+		seen[c.Path] = true
+	}
+
+	for _, file := range allFiles {
+		if seen[file] || matchesAny(file, cfg.ProtectPaths) || matchesAny(file, cfg.ExcludeGlobs) {
+			continue
+		}
+		if matchesAny(file, cfg.IncludeGlobs) {
+			result = append(result, candidate{Path: file, Reason: "matched include glob"})
+			seen[file] = true
+		}
+	}
+
+	return result
+}
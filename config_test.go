@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		patterns []string
+		want     bool
+	}{
+		{name: "no patterns", path: "a.txt", patterns: nil, want: false},
+		{name: "exact match", path: "a.txt", patterns: []string{"a.txt"}, want: true},
+		{name: "no match", path: "a.txt", patterns: []string{"b.txt"}, want: false},
+		{name: "double star match", path: "build/out/bin.exe", patterns: []string{"build/**"}, want: true},
+		{name: "single star does not cross dirs", path: "build/out/bin.exe", patterns: []string{"build/*"}, want: false},
+		{name: "second pattern matches", path: "secrets.env", patterns: []string{"*.txt", "secrets.*"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesAny(tt.path, tt.patterns); got != tt.want {
+				t.Errorf("matchesAny(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	allFiles := []string{"a.txt", "build/out.bin", "secret.pem", "keep.txt"}
+
+	tests := []struct {
+		name       string
+		cfg        *Config
+		candidates []candidate
+		want       []string
+	}{
+		{
+			name:       "include glob surfaces an unflagged path",
+			cfg:        &Config{IncludeGlobs: []string{"*.pem"}},
+			candidates: nil,
+			want:       []string{"secret.pem"},
+		},
+		{
+			name:       "exclude glob takes precedence over include glob",
+			cfg:        &Config{IncludeGlobs: []string{"build/**"}, ExcludeGlobs: []string{"build/**"}},
+			candidates: nil,
+			want:       nil,
+		},
+		{
+			name:       "protect path drops an existing candidate",
+			cfg:        &Config{ProtectPaths: []string{"a.txt"}},
+			candidates: []candidate{{Path: "a.txt", Reason: "gitignored"}},
+			want:       nil,
+		},
+		{
+			name:       "protect path keeps a file out even if include glob matches",
+			cfg:        &Config{IncludeGlobs: []string{"*.txt"}, ProtectPaths: []string{"keep.txt"}},
+			candidates: nil,
+			want:       []string{"a.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyConfig(tt.cfg, tt.candidates, allFiles)
+			gotPaths := candidatePaths(got)
+			if !reflect.DeepEqual(gotPaths, tt.want) && !(len(gotPaths) == 0 && len(tt.want) == 0) {
+				t.Errorf("applyConfig() paths = %v, want %v", gotPaths, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+)
+
+// commitsTouchingPath returns, oldest first, the hashes of every commit
+// across all branches that touched path.
+func commitsTouchingPath(path string) []string {
+	cmd := exec.Command("git", "log", "--all", "--reverse", "--format=%H", "--", path)
+	lines, err := runCmdAndGetOutputLines(cmd)
+	if err != nil {
+		log.Fatalf("Could not walk history of %s: %v", path, err)
+	}
+
+	commits := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if hash := strings.TrimSpace(line); hash != "" {
+			commits = append(commits, hash)
+		}
+	}
+	return commits
+}
+
+// refsContainingCommit looks up, via a single
+// https://git-scm.com/docs/git-for-each-ref --contains invocation, the
+// branches and tags that contain commit.
+func refsContainingCommit(commit string) []string {
+	cmd := exec.Command("git", "for-each-ref", "--contains", commit, "--format=%(refname:short)")
+	lines, err := runCmdAndGetOutputLines(cmd)
+	if err != nil {
+		log.Fatalf("Could not find refs containing %s: %v", commit, err)
+	}
+
+	refs := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if ref := strings.TrimSpace(line); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// refsContainingCommits returns the union of branches and tags that
+// contain any of commits. A ref reached via an independent re-add,
+// rebase or cherry-pick may contain the path without containing its
+// oldest touching commit, so every touching commit needs to be checked
+// rather than just one. refsCache memoizes refsContainingCommit across
+// calls, since printDryRunReport calls this once per candidate path and
+// the same commit (e.g. a repo-wide initial commit) commonly touches
+// many of them — without it, callers would spawn one git process per
+// commit per path instead of once per distinct commit overall.
+func refsContainingCommits(commits []string, refsCache map[string][]string) []string {
+	seen := make(map[string]bool)
+	refs := make([]string, 0)
+
+	for _, commit := range commits {
+		commitRefs, ok := refsCache[commit]
+		if !ok {
+			commitRefs = refsContainingCommit(commit)
+			refsCache[commit] = commitRefs
+		}
+
+		for _, ref := range commitRefs {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	return refs
+}
+
+// printDryRunReport prints, for every candidate path, how many bytes
+// removing it would reclaim and which commits/branches/tags it touches,
+// without rewriting any history.
+func printDryRunReport(candidates []candidate) {
+	sizeByPath := make(map[string]blobSizeInfo)
+	for _, info := range computeBlobSizeInfo() {
+		sizeByPath[info.Path] = info
+	}
+
+	fmt.Print(ConsoleGreenColorCode)
+	fmt.Println("Dry run: no history will be rewritten. Here's what removing these paths would reclaim:")
+	fmt.Print(ConsoleResetColorCode)
+
+	refsCache := make(map[string][]string)
+
+	var totalUncompressed, totalDisk int64
+	for _, c := range candidates {
+		info := sizeByPath[c.Path]
+		totalUncompressed += info.UncompressedSize
+		totalDisk += info.DiskSize
+
+		commits := commitsTouchingPath(c.Path)
+		refs := refsContainingCommits(commits, refsCache)
+
+		fmt.Printf("\n%s (%s)\n", c.Path, c.Reason)
+		fmt.Printf("  reclaims: %s (%s on disk)\n", humanizeBytes(info.UncompressedSize), humanizeBytes(info.DiskSize))
+		fmt.Printf("  commits: %d\n", len(commits))
+		fmt.Printf("  refs touched: %s\n", strings.Join(refs, ", "))
+	}
+
+	fmt.Printf("\nTotal: %s (%s on disk) across %d path(s)\n", humanizeBytes(totalUncompressed), humanizeBytes(totalDisk), len(candidates))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+)
+
+// chunkFiles splits files into batches of at most batchSize paths. A
+// batchSize <= 0 means no chunking: all files are returned as a single
+// batch.
+func chunkFiles(files []string, batchSize int) [][]string {
+	if batchSize <= 0 || len(files) <= batchSize {
+		return [][]string{files}
+	}
+
+	batches := make([][]string, 0, (len(files)+batchSize-1)/batchSize)
+	for start := 0; start < len(files); start += batchSize {
+		end := start + batchSize
+		if end > len(files) {
+			end = len(files)
+		}
+		batches = append(batches, files[start:end])
+	}
+	return batches
+}
+
+// writePathsFile writes one path per line to a temp file suitable for
+// https://github.com/newren/git-filter-repo#filtering-based-on-paths
+// --paths-from-file.
+func writePathsFile(files []string) (string, error) {
+	f, err := os.CreateTemp("", "git-repo-cleaner-paths-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	writer := bufio.NewWriter(f)
+	for _, file := range files {
+		fmt.Fprintln(writer, file)
+	}
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// runFilterRepoBatch invokes git filter-repo once to remove every path
+// in files, echoing filter-repo's stderr as it runs so the user still
+// sees progress even though the whole batch is a single invocation.
+func runFilterRepoBatch(files []string) {
+	pathsFile, err := writePathsFile(files)
+	if err != nil {
+		log.Fatalf("Could not write paths file for git filter-repo: %v", err)
+	}
+	defer os.Remove(pathsFile)
+
+	fmt.Println()
+	fmt.Print(ConsoleGreenColorCode)
+	fmt.Printf("Will start removing %d file(s):\n", len(files))
+	for _, file := range files {
+		fmt.Println(file)
+	}
+	fmt.Println(ConsoleResetColorCode)
+
+	cmd := exec.Command("git", "filter-repo", "--force", "--invert-paths", "--paths-from-file", pathsFile)
+	cmd.Dir = *repoAbsolutePath
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		log.Fatalf("Could not open stderr from git filter-repo: %v", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		log.Fatalf("Could not start git filter-repo: %v", err)
+	}
+
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		fmt.Println(scanner.Text())
+	}
+
+	if err := cmd.Wait(); err != nil {
+		log.Fatalf("Failed to rewrite history to remove %d file(s): %v", len(files), err)
+	}
+}
+
+// removeFilesFromHistory applies
+// https://github.com/newren/git-filter-repo/blob/main/COPYING
+// on all of the files to be removed. Paths are batched into as few
+// git filter-repo invocations as possible (tunable via --batch-size)
+// instead of one invocation per file, since each invocation rewrites
+// the entire history.
+func removeFilesFromHistory(files []string) {
+	for _, batch := range chunkFiles(files, *batchSize) {
+		if len(batch) == 0 {
+			continue
+		}
+		runFilterRepoBatch(batch)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChunkFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		batchSize int
+		want      [][]string
+	}{
+		{
+			name:      "batchSize <= 0 means no chunking",
+			files:     []string{"a", "b", "c"},
+			batchSize: 0,
+			want:      [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:      "fewer files than batchSize",
+			files:     []string{"a", "b"},
+			batchSize: 5,
+			want:      [][]string{{"a", "b"}},
+		},
+		{
+			name:      "exact multiple of batchSize",
+			files:     []string{"a", "b", "c", "d"},
+			batchSize: 2,
+			want:      [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name:      "remainder batch",
+			files:     []string{"a", "b", "c"},
+			batchSize: 2,
+			want:      [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name:      "empty files",
+			files:     []string{},
+			batchSize: 2,
+			want:      [][]string{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chunkFiles(tt.files, tt.batchSize)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunkFiles(%v, %d) = %v, want %v", tt.files, tt.batchSize, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"golang.org/x/term"
+)
+
+// shouldUseInteractivePicker reports whether the terminal picker should
+// be used, falling back to the plain stdout prompt when stdin/stdout
+// aren't a TTY (e.g. in CI).
+func shouldUseInteractivePicker() bool {
+	return *interactive &&
+		term.IsTerminal(int(os.Stdin.Fd())) &&
+		term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// pickerRow is a single candidate as displayed by the picker, together
+// with whether the user currently has it selected for removal.
+type pickerRow struct {
+	candidate
+	UncompressedSize int64
+	CommitCount      int
+	Selected         bool
+}
+
+// runInteractivePicker lets the user toggle each candidate path with
+// space, filter by substring, and confirm with enter. It returns the
+// selected candidates and false if the user cancelled instead.
+func runInteractivePicker(candidates []candidate) ([]candidate, bool) {
+	sizeByPath := make(map[string]blobSizeInfo)
+	for _, info := range computeBlobSizeInfo() {
+		sizeByPath[info.Path] = info
+	}
+
+	rows := make([]pickerRow, 0, len(candidates))
+	for _, c := range candidates {
+		info := sizeByPath[c.Path]
+		rows = append(rows, pickerRow{
+			candidate:        c,
+			UncompressedSize: info.UncompressedSize,
+			CommitCount:      info.CommitCount,
+			Selected:         true,
+		})
+	}
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		log.Fatalf("Could not create terminal picker: %v", err)
+	}
+	if err := screen.Init(); err != nil {
+		log.Fatalf("Could not initialize terminal picker: %v", err)
+	}
+	defer screen.Fini()
+
+	var filter string
+	cursor := 0
+
+	for {
+		visible := visibleRowIndices(rows, filter)
+		if cursor >= len(visible) {
+			cursor = len(visible) - 1
+		}
+		if cursor < 0 {
+			cursor = 0
+		}
+
+		drawPicker(screen, rows, visible, cursor, filter)
+
+		ev := screen.PollEvent()
+		switch ev := ev.(type) {
+		case *tcell.EventKey:
+			switch {
+			case ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC:
+				return nil, false
+			case ev.Key() == tcell.KeyEnter:
+				return selectedCandidates(rows), true
+			case ev.Key() == tcell.KeyUp:
+				if cursor > 0 {
+					cursor--
+				}
+			case ev.Key() == tcell.KeyDown:
+				if cursor < len(visible)-1 {
+					cursor++
+				}
+			case ev.Rune() == ' ':
+				if len(visible) > 0 {
+					rows[visible[cursor]].Selected = !rows[visible[cursor]].Selected
+				}
+			case ev.Key() == tcell.KeyBackspace || ev.Key() == tcell.KeyBackspace2:
+				if len(filter) > 0 {
+					filter = filter[:len(filter)-1]
+				}
+			case ev.Rune() != 0:
+				filter += string(ev.Rune())
+			}
+		case *tcell.EventResize:
+			screen.Sync()
+		}
+	}
+}
+
+// visibleRowIndices returns the indices of rows whose path contains
+// filter, case-insensitively.
+func visibleRowIndices(rows []pickerRow, filter string) []int {
+	indices := make([]int, 0, len(rows))
+	lowerFilter := strings.ToLower(filter)
+	for i, row := range rows {
+		if filter == "" || strings.Contains(strings.ToLower(row.Path), lowerFilter) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// selectedCandidates returns the candidate of every row still marked
+// Selected.
+func selectedCandidates(rows []pickerRow) []candidate {
+	selected := make([]candidate, 0, len(rows))
+	for _, row := range rows {
+		if row.Selected {
+			selected = append(selected, row.candidate)
+		}
+	}
+	return selected
+}
+
+// drawPicker renders the current picker state to screen.
+func drawPicker(screen tcell.Screen, rows []pickerRow, visible []int, cursor int, filter string) {
+	screen.Clear()
+
+	header := fmt.Sprintf("Select paths to remove from history (space: toggle, enter: confirm, esc: cancel) | filter: %s", filter)
+	drawText(screen, 0, 0, tcell.StyleDefault.Bold(true), header)
+
+	for i, rowIdx := range visible {
+		row := rows[rowIdx]
+
+		checkbox := "[ ]"
+		if row.Selected {
+			checkbox = "[x]"
+		}
+
+		line := fmt.Sprintf("%s %s (%s, %s, %d commits)", checkbox, row.Path, row.Reason, humanizeBytes(row.UncompressedSize), row.CommitCount)
+
+		style := tcell.StyleDefault
+		if i == cursor {
+			style = style.Reverse(true)
+		}
+
+		drawText(screen, 0, i+2, style, line)
+	}
+
+	screen.Show()
+}
+
+// drawText writes s to screen starting at (x, y) with the given style.
+func drawText(screen tcell.Screen, x, y int, style tcell.Style, s string) {
+	for i, r := range s {
+		screen.SetContent(x+i, y, r, nil, style)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// scanSecrets, when set, walks every blob in history looking for
+// leaked credentials and adds any hit to the removal candidate set.
+var scanSecrets = flag.Bool("scan-secrets", false, "Scan every blob in history for leaked secrets and add hits to the removal candidates")
+
+// SecretDetector is a single pluggable rule used by the secret scanner.
+// Detectors declared in a config file are merged with the built-in
+// ones below.
+type SecretDetector struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// builtinSecretDetectors are the detectors enabled by default.
+var builtinSecretDetectors = []SecretDetector{
+	{Name: "aws-access-key-id", Pattern: `AKIA[0-9A-Z]{16}`},
+	{Name: "github-token", Pattern: `ghp_[A-Za-z0-9]{36}`},
+	{Name: "gcp-service-account-key", Pattern: `"type":\s*"service_account"`},
+	{Name: "private-key-pem", Pattern: `-----BEGIN (RSA |EC |OPENSSH |DSA |)PRIVATE KEY-----`},
+}
+
+// minHighEntropyLineLen and highEntropyThreshold gate the generic
+// high-entropy-string detector: short lines are too noisy, and 4.5
+// bits/char reliably separates prose/code from secrets in practice.
+const (
+	minHighEntropyLineLen = 20
+	highEntropyThreshold  = 4.5
+)
+
+// compiledDetector is a SecretDetector with its pattern compiled.
+type compiledDetector struct {
+	Name  string
+	Regex *regexp.Regexp
+}
+
+// compileDetectors merges the built-in detectors with any declared in
+// cfg and compiles their patterns.
+func compileDetectors(cfg *Config) ([]compiledDetector, error) {
+	all := append([]SecretDetector{}, builtinSecretDetectors...)
+	if cfg != nil {
+		all = append(all, cfg.SecretDetectors...)
+	}
+
+	compiled := make([]compiledDetector, 0, len(all))
+	for _, d := range all {
+		re, err := regexp.Compile(d.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret detector %q: %w", d.Name, err)
+		}
+		compiled = append(compiled, compiledDetector{Name: d.Name, Regex: re})
+	}
+	return compiled, nil
+}
+
+// scanBlobsForSecrets streams the contents of every blob ever saved in
+// git through detectors, and returns one candidate per path with a hit,
+// annotated with the detector name and a redacted preview of the match.
+func scanBlobsForSecrets(detectors []compiledDetector) []candidate {
+	pathObjects := pathToObjects()
+	objectToPaths := make(map[string][]string)
+	allObjectIDs := make([]string, 0, len(pathObjects))
+	for path, ids := range pathObjects {
+		for _, id := range ids {
+			if len(objectToPaths[id]) == 0 {
+				allObjectIDs = append(allObjectIDs, id)
+			}
+			objectToPaths[id] = append(objectToPaths[id], path)
+		}
+	}
+
+	// git rev-list --objects --all also returns tree (directory) object
+	// ids, not just blobs; streaming those into the detectors below would
+	// run regex/entropy checks on raw binary tree data. batchObjectSizes
+	// only records an entry for objects it confirms are blobs, so reuse
+	// it as a --batch-check pass to filter those out first.
+	blobSizes := batchObjectSizes(allObjectIDs)
+	objectIDs := make([]string, 0, len(blobSizes))
+	for _, id := range allObjectIDs {
+		if _, ok := blobSizes[id]; ok {
+			objectIDs = append(objectIDs, id)
+		}
+	}
+
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = *repoAbsolutePath
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		log.Fatalf("Could not open stdin to git cat-file: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		log.Fatalf("Could not open stdout from git cat-file: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		log.Fatalf("Could not start git cat-file: %v", err)
+	}
+
+	go func() {
+		writer := bufio.NewWriter(stdin)
+		for _, id := range objectIDs {
+			fmt.Fprintln(writer, id)
+		}
+		writer.Flush()
+		stdin.Close()
+	}()
+
+	reasonByPath := make(map[string]string)
+	detailByPath := make(map[string]string)
+
+	reader := bufio.NewReader(stdout)
+	for _, id := range objectIDs {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		fields := strings.Fields(header)
+		if len(fields) < 3 {
+			continue
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		content := make([]byte, size)
+		if _, err := io.ReadFull(reader, content); err != nil {
+			log.Fatalf("Could not read blob %s: %v", id, err)
+		}
+		reader.Discard(1) // trailing newline after the blob's content
+
+		name, lineNo, redacted, hit := scanContentForSecrets(content, detectors)
+		if !hit {
+			continue
+		}
+		for _, path := range objectToPaths[id] {
+			if _, ok := reasonByPath[path]; ok {
+				continue
+			}
+			reasonByPath[path] = fmt.Sprintf("secret:%s", name)
+			detailByPath[path] = fmt.Sprintf("line %d: %s", lineNo, redacted)
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		log.Fatalf("git cat-file failed: %v", err)
+	}
+
+	candidates := make([]candidate, 0, len(reasonByPath))
+	for path, reason := range reasonByPath {
+		candidates = append(candidates, candidate{Path: path, Reason: reason, Detail: detailByPath[path]})
+	}
+	return candidates
+}
+
+// scanContentForSecrets runs every detector, plus a generic
+// high-entropy-string check, over content line by line and returns the
+// first hit.
+func scanContentForSecrets(content []byte, detectors []compiledDetector) (name string, line int, redacted string, hit bool) {
+	lines := strings.Split(string(content), "\n")
+	for i, l := range lines {
+		for _, d := range detectors {
+			if d.Regex.MatchString(l) {
+				return d.Name, i + 1, redactLine(l), true
+			}
+		}
+		if len(l) > minHighEntropyLineLen && shannonEntropy(l) > highEntropyThreshold {
+			return "high-entropy-string", i + 1, redactLine(l), true
+		}
+	}
+	return "", 0, "", false
+}
+
+// shannonEntropy returns the Shannon entropy of s, in bits per
+// character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// redactLine keeps a few characters at each end of l, for context,
+// and replaces the rest with asterisks.
+func redactLine(l string) string {
+	l = strings.TrimSpace(l)
+	if len(l) <= 8 {
+		return strings.Repeat("*", len(l))
+	}
+	return l[:4] + strings.Repeat("*", len(l)-8) + l[len(l)-4:]
+}
+
+// mergeCandidates combines base with extra. A path present in both keeps
+// its place in base, but has extra's Reason/Detail folded in rather than
+// discarded, so e.g. a secret hit on a path another heuristic already
+// flagged still surfaces its detector name and redacted evidence line.
+func mergeCandidates(base, extra []candidate) []candidate {
+	result := append([]candidate{}, base...)
+	indexByPath := make(map[string]int, len(result))
+	for i, c := range result {
+		indexByPath[c.Path] = i
+	}
+
+	for _, c := range extra {
+		if i, ok := indexByPath[c.Path]; ok {
+			result[i] = combineCandidates(result[i], c)
+			continue
+		}
+		indexByPath[c.Path] = len(result)
+		result = append(result, c)
+	}
+	return result
+}
+
+// combineCandidates folds b's Reason/Detail into a, appending rather
+// than overwriting so neither heuristic's findings are lost.
+func combineCandidates(a, b candidate) candidate {
+	merged := a
+	if b.Reason != "" && b.Reason != merged.Reason {
+		if merged.Reason == "" {
+			merged.Reason = b.Reason
+		} else {
+			merged.Reason = merged.Reason + "; " + b.Reason
+		}
+	}
+	if b.Detail != "" {
+		if merged.Detail == "" {
+			merged.Detail = b.Detail
+		} else {
+			merged.Detail = merged.Detail + "; " + b.Detail
+		}
+	}
+	return merged
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMergeCandidates(t *testing.T) {
+	base := []candidate{
+		{Path: "a.txt", Reason: "gitignored"},
+		{Path: "b.txt", Reason: "missing on main branch"},
+	}
+	extra := []candidate{
+		{Path: "b.txt", Reason: "secret:aws-access-key-id", Detail: "line 3: AKIA****key"},
+		{Path: "c.txt", Reason: "secret:github-token", Detail: "line 1: ghp_****oken"},
+	}
+
+	got := mergeCandidates(base, extra)
+	byPath := make(map[string]candidate, len(got))
+	for _, c := range got {
+		byPath[c.Path] = c
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("mergeCandidates() returned %d candidates, want 3: %v", len(got), got)
+	}
+
+	b := byPath["b.txt"]
+	if b.Reason != "missing on main branch; secret:aws-access-key-id" {
+		t.Errorf("b.txt Reason = %q, want both reasons combined", b.Reason)
+	}
+	if b.Detail != "line 3: AKIA****key" {
+		t.Errorf("b.txt Detail = %q, want the secret's detail preserved", b.Detail)
+	}
+
+	c := byPath["c.txt"]
+	if c.Reason != "secret:github-token" {
+		t.Errorf("c.txt Reason = %q, want %q", c.Reason, "secret:github-token")
+	}
+
+	a := byPath["a.txt"]
+	if a.Reason != "gitignored" {
+		t.Errorf("a.txt Reason = %q, want untouched %q", a.Reason, "gitignored")
+	}
+}
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want float64
+	}{
+		{name: "empty string", in: "", want: 0},
+		{name: "single repeated char has zero entropy", in: "aaaaaa", want: 0},
+		{name: "two equally likely chars", in: "abab", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shannonEntropy(tt.in)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("shannonEntropy(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactLine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "short line is fully redacted", in: "short", want: "*****"},
+		{name: "exactly 8 chars is fully redacted", in: "12345678", want: "********"},
+		{name: "long line keeps 4 chars at each end", in: "AKIA1234567890EXAMPLE", want: "AKIA*************MPLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactLine(tt.in); got != tt.want {
+				t.Errorf("redactLine(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}